@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// Collector streams container stats in the background and keeps the
+// latest sample for every running container in a shared table, so
+// callers never block on a Docker API round trip. A container-event
+// listener starts and stops per-container goroutines as containers come
+// and go, evicting stopped containers from the table.
+type Collector struct {
+	client *client.Client
+	ctx    context.Context
+	osType string
+	filter ContainerFilter
+
+	mu      sync.RWMutex
+	stats   map[string]ContainerStats
+	labels  map[string]map[string]string
+	cancels map[string]context.CancelFunc
+
+	notify chan struct{}
+}
+
+// NewCollector creates a Collector bound to the given Docker client,
+// tracking only containers that match filter. The returned Collector
+// does nothing until Run is called.
+func NewCollector(ctx context.Context, cli *client.Client, filter ContainerFilter) *Collector {
+	return &Collector{
+		client:  cli,
+		ctx:     ctx,
+		filter:  filter,
+		stats:   make(map[string]ContainerStats),
+		labels:  make(map[string]map[string]string),
+		cancels: make(map[string]context.CancelFunc),
+		notify:  make(chan struct{}, 1),
+	}
+}
+
+// Updates returns a channel that receives a value whenever one or more
+// containers have a new stats sample. Reads are coalesced: a burst of
+// updates across many per-container goroutines collapses into a single
+// pending signal, so subscribers should always re-read Snapshot rather
+// than assume one value per update.
+func (c *Collector) Updates() <-chan struct{} {
+	return c.notify
+}
+
+// UpdateDisk merges disk usage figures into containerID's latest
+// sample, leaving its other fields untouched. It's a no-op if the
+// container isn't currently tracked. Used by the -disk poller, which
+// runs on its own slower ticker since SizeRootFs is expensive to
+// compute on the daemon side.
+func (c *Collector) UpdateDisk(containerID string, bytes, inodes uint64) {
+	c.mu.Lock()
+	stat, ok := c.stats[containerID]
+	if ok {
+		stat.DiskUsageBytes = bytes
+		stat.DiskUsageInodes = inodes
+		c.stats[containerID] = stat
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Run bootstraps collectors for already-running containers and then
+// watches Docker events to start a per-container collector when a
+// container starts and stop it when the container dies. Run blocks
+// until its context is cancelled.
+func (c *Collector) Run() error {
+	info, err := c.client.Info(c.ctx)
+	if err != nil {
+		log.Printf("Warning: failed to query Docker daemon info, assuming linux: %v", err)
+		c.osType = "linux"
+	} else {
+		c.osType = info.OSType
+	}
+
+	listOpts := container.ListOptions{All: c.filter.All}
+	if len(c.filter.Labels) > 0 {
+		args := filters.NewArgs()
+		for _, kv := range c.filter.Labels {
+			args.Add("label", kv)
+		}
+		listOpts.Filters = args
+	}
+
+	containers, err := c.client.ContainerList(c.ctx, listOpts)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+	for _, ctr := range containers {
+		name := containerDisplayName(ctr.Names, ctr.ID)
+		if !c.filter.matches(name, ctr.ID, ctr.Labels) {
+			continue
+		}
+		c.start(ctr.ID, name, ctr.Labels)
+	}
+
+	filterArgs := filters.NewArgs(
+		filters.Arg("type", string(events.ContainerEventType)),
+		filters.Arg("event", string(events.ActionStart)),
+		filters.Arg("event", string(events.ActionDie)),
+	)
+	msgCh, errCh := c.client.Events(c.ctx, events.ListOptions{Filters: filterArgs})
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return nil
+		case err := <-errCh:
+			if err != nil {
+				return fmt.Errorf("docker event stream error: %w", err)
+			}
+		case msg := <-msgCh:
+			switch msg.Action {
+			case events.ActionStart:
+				ctr, err := c.client.ContainerInspect(c.ctx, msg.Actor.ID)
+				if err != nil {
+					log.Printf("Warning: failed to inspect started container %s: %v", shortID(msg.Actor.ID), err)
+					continue
+				}
+				labels := map[string]string{}
+				if ctr.Config != nil {
+					labels = ctr.Config.Labels
+				}
+				name := strings.TrimPrefix(ctr.Name, "/")
+				if !c.filter.matches(name, msg.Actor.ID, labels) {
+					continue
+				}
+				c.start(msg.Actor.ID, name, labels)
+			case events.ActionDie:
+				c.stop(msg.Actor.ID)
+			}
+		}
+	}
+}
+
+// start launches a streaming-stats goroutine for containerID if one
+// isn't already running.
+func (c *Collector) start(containerID, name string, labels map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.cancels[containerID]; exists {
+		return
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.cancels[containerID] = cancel
+	c.labels[containerID] = labels
+	go c.stream(ctx, containerID, name)
+}
+
+// stop cancels containerID's streaming goroutine, if any, and evicts it
+// from the stats and label tables.
+func (c *Collector) stop(containerID string) {
+	c.mu.Lock()
+	cancel, ok := c.cancels[containerID]
+	delete(c.cancels, containerID)
+	delete(c.stats, containerID)
+	delete(c.labels, containerID)
+	c.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// LabelValues returns the values of the requested container label keys
+// for containerID, reporting an empty string for any key that isn't
+// set on the container.
+func (c *Collector) LabelValues(containerID string, keys []string) []string {
+	c.mu.RLock()
+	labels := c.labels[containerID]
+	c.mu.RUnlock()
+
+	values := make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = labels[k]
+	}
+	return values
+}
+
+// stream opens the Docker streaming stats endpoint for containerID and
+// decodes successive frames into the shared stats table until ctx is
+// cancelled or the stream ends.
+func (c *Collector) stream(ctx context.Context, containerID, name string) {
+	resp, err := c.client.ContainerStats(ctx, containerID, true)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Warning: failed to stream stats for container %s: %v", shortID(containerID), err)
+		}
+		c.stop(containerID)
+		return
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var v container.StatsResponse
+		if err := dec.Decode(&v); err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Warning: stats stream for container %s ended: %v", shortID(containerID), err)
+				c.stop(containerID)
+			}
+			return
+		}
+
+		stat := statFromResponse(containerID, name, c.osType, &v)
+		c.mu.Lock()
+		if _, tracked := c.cancels[containerID]; !tracked {
+			c.mu.Unlock()
+			return
+		}
+		if prev, ok := c.stats[containerID]; ok {
+			stat.DiskUsageBytes = prev.DiskUsageBytes
+			stat.DiskUsageInodes = prev.DiskUsageInodes
+		}
+		c.stats[containerID] = stat
+		c.mu.Unlock()
+
+		select {
+		case c.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Entries returns the latest known sample for every tracked container,
+// keyed by full container ID, for callers that need to correlate a
+// sample with its container's metadata (e.g. the Prometheus exporter
+// looking up labels).
+func (c *Collector) Entries() map[string]ContainerStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := make(map[string]ContainerStats, len(c.stats))
+	for id, s := range c.stats {
+		out[id] = s
+	}
+	return out
+}
+
+// Snapshot returns the latest known sample for every tracked container,
+// sorted by name for stable rendering.
+func (c *Collector) Snapshot() []ContainerStats {
+	return c.FilteredSnapshot(ContainerFilter{})
+}
+
+// FilteredSnapshot is like Snapshot but further narrows the result to
+// containers matching filter, e.g. the ?name=&label= query params on
+// /api/stats. It's a narrowing filter on top of whatever the Collector
+// is already tracking, not a replacement for the -container/-label/-all
+// flags that decide what gets streamed in the first place.
+func (c *Collector) FilteredSnapshot(filter ContainerFilter) []ContainerStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := make([]ContainerStats, 0, len(c.stats))
+	for id, s := range c.stats {
+		if !filter.matches(s.Name, id, c.labels[id]) {
+			continue
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// statFromResponse converts a decoded stats frame into a ContainerStats.
+func statFromResponse(containerID, name, osType string, v *container.StatsResponse) ContainerStats {
+	cpuPercent := calculateCPUPercent(osType, v)
+	memUsage := memoryUsageWithoutCache(v)
+
+	var memPercent float64
+	if v.MemoryStats.Limit > 0 {
+		memPercent = float64(memUsage) / float64(v.MemoryStats.Limit) * 100.0
+	}
+
+	var rxBytes, txBytes uint64
+	for _, network := range v.Networks {
+		rxBytes += network.RxBytes
+		txBytes += network.TxBytes
+	}
+
+	var blockRead, blockWrite uint64
+	for _, bio := range v.BlkioStats.IoServiceBytesRecursive {
+		if bio.Op == "Read" {
+			blockRead += bio.Value
+		} else if bio.Op == "Write" {
+			blockWrite += bio.Value
+		}
+	}
+
+	return ContainerStats{
+		ID:            shortID(containerID),
+		Name:          name,
+		CPUPercent:    cpuPercent,
+		MemoryUsage:   memUsage,
+		MemoryLimit:   v.MemoryStats.Limit,
+		MemoryPercent: memPercent,
+		NetworkRx:     rxBytes,
+		NetworkTx:     txBytes,
+		BlockRead:     blockRead,
+		BlockWrite:    blockWrite,
+	}
+}
+
+// calculateCPUPercent calculates the CPU usage percentage, dispatching
+// to the Unix or Windows formula based on the daemon's OSType since the
+// two report incompatible stats shapes.
+func calculateCPUPercent(osType string, v *container.StatsResponse) float64 {
+	if osType == "windows" {
+		return calculateCPUPercentWindows(v)
+	}
+	return calculateCPUPercentUnix(v)
+}
+
+// calculateCPUPercentUnix calculates CPU usage percentage for Linux
+// containers. It prefers OnlineCPUs, which is populated on cgroup v2
+// and by modern daemons; len(PercpuUsage) is empty under cgroup v2, so
+// it's only used as a fallback, with runtime.NumCPU() as a last resort.
+func calculateCPUPercentUnix(v *container.StatsResponse) float64 {
+	cpuDelta := float64(v.CPUStats.CPUUsage.TotalUsage) - float64(v.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(v.CPUStats.SystemUsage) - float64(v.PreCPUStats.SystemUsage)
+	if systemDelta <= 0.0 || cpuDelta <= 0.0 {
+		return 0.0
+	}
+	return (cpuDelta / systemDelta) * onlineCPUs(v) * 100.0
+}
+
+// onlineCPUs returns the number of CPUs visible to the container.
+func onlineCPUs(v *container.StatsResponse) float64 {
+	if v.CPUStats.OnlineCPUs > 0 {
+		return float64(v.CPUStats.OnlineCPUs)
+	}
+	if n := len(v.CPUStats.CPUUsage.PercpuUsage); n > 0 {
+		return float64(n)
+	}
+	return float64(runtime.NumCPU())
+}
+
+// calculateCPUPercentWindows calculates CPU usage percentage for
+// Windows containers. Windows stats have no SystemUsage field, so
+// usage is measured against wall-clock time between reads instead.
+func calculateCPUPercentWindows(v *container.StatsResponse) float64 {
+	durationNanos := v.Read.Sub(v.PreRead).Nanoseconds()
+	if durationNanos <= 0 {
+		return 0.0
+	}
+
+	numProcs := float64(v.NumProcs)
+	if numProcs == 0 {
+		numProcs = float64(runtime.NumCPU())
+	}
+
+	cpuDelta := float64(v.CPUStats.CPUUsage.TotalUsage) - float64(v.PreCPUStats.CPUUsage.TotalUsage)
+	return (cpuDelta / float64(durationNanos)) / numProcs * 100.0
+}
+
+// memoryUsageWithoutCache mirrors how the official Docker CLI and
+// telegraf report memory usage: page cache counted by the kernel is
+// reclaimable and shouldn't be charged to the container. cgroup v2
+// reports it as "inactive_file"; cgroup v1 reports it as "cache".
+func memoryUsageWithoutCache(v *container.StatsResponse) uint64 {
+	usage := v.MemoryStats.Usage
+	if cache, ok := v.MemoryStats.Stats["inactive_file"]; ok && cache < usage {
+		return usage - cache
+	}
+	if cache, ok := v.MemoryStats.Stats["cache"]; ok && cache < usage {
+		return usage - cache
+	}
+	return usage
+}
+
+// containerDisplayName picks the best human-readable name for a
+// container returned by ContainerList, falling back to its short ID.
+func containerDisplayName(names []string, id string) string {
+	if len(names) > 0 {
+		return strings.TrimPrefix(names[0], "/")
+	}
+	return shortID(id)
+}
+
+// shortID truncates a container ID to the 12-character form used
+// throughout the CLI and dashboard.
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
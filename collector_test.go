@@ -0,0 +1,175 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestCalculateCPUPercentUnix(t *testing.T) {
+	cases := []struct {
+		name string
+		v    *container.StatsResponse
+		want float64
+	}{
+		{
+			name: "cgroup v1 with percpu usage",
+			v: &container.StatsResponse{
+				Stats: container.Stats{
+					CPUStats: container.CPUStats{
+						CPUUsage:    container.CPUUsage{TotalUsage: 2000000000, PercpuUsage: []uint64{0, 0}},
+						SystemUsage: 20000000000,
+					},
+					PreCPUStats: container.CPUStats{
+						CPUUsage:    container.CPUUsage{TotalUsage: 1000000000},
+						SystemUsage: 10000000000,
+					},
+				},
+			},
+			// cpuDelta=1e9, systemDelta=1e10, onlineCPUs falls back to len(PercpuUsage)=2
+			want: 20.0,
+		},
+		{
+			name: "cgroup v2 reports OnlineCPUs instead of PercpuUsage",
+			v: &container.StatsResponse{
+				Stats: container.Stats{
+					CPUStats: container.CPUStats{
+						CPUUsage:    container.CPUUsage{TotalUsage: 4000000000},
+						SystemUsage: 20000000000,
+						OnlineCPUs:  4,
+					},
+					PreCPUStats: container.CPUStats{
+						CPUUsage:    container.CPUUsage{TotalUsage: 2000000000},
+						SystemUsage: 10000000000,
+					},
+				},
+			},
+			// cpuDelta=2e9, systemDelta=1e10 -> 20% * 4 OnlineCPUs
+			want: 80.0,
+		},
+		{
+			name: "no system delta yields zero instead of dividing by zero",
+			v: &container.StatsResponse{
+				Stats: container.Stats{
+					CPUStats: container.CPUStats{
+						CPUUsage:    container.CPUUsage{TotalUsage: 2000000000},
+						SystemUsage: 10000000000,
+					},
+					PreCPUStats: container.CPUStats{
+						CPUUsage:    container.CPUUsage{TotalUsage: 1000000000},
+						SystemUsage: 10000000000,
+					},
+				},
+			},
+			want: 0.0,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := calculateCPUPercentUnix(tc.v)
+			if got != tc.want {
+				t.Errorf("calculateCPUPercentUnix() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCalculateCPUPercentWindows(t *testing.T) {
+	read := time.Now()
+	preRead := read.Add(-1 * time.Second)
+
+	v := &container.StatsResponse{
+		Stats: container.Stats{
+			Read:     read,
+			PreRead:  preRead,
+			NumProcs: 2,
+			CPUStats: container.CPUStats{
+				CPUUsage: container.CPUUsage{TotalUsage: 2000000000},
+			},
+			PreCPUStats: container.CPUStats{
+				CPUUsage: container.CPUUsage{TotalUsage: 1000000000},
+			},
+		},
+	}
+
+	// cpuDelta=1e9ns over 1s (1e9ns) wall clock, split across 2 procs -> 50%
+	got := calculateCPUPercentWindows(v)
+	if got != 50.0 {
+		t.Errorf("calculateCPUPercentWindows() = %v, want 50.0", got)
+	}
+}
+
+func TestCalculateCPUPercentWindowsZeroDuration(t *testing.T) {
+	now := time.Now()
+	v := &container.StatsResponse{Stats: container.Stats{Read: now, PreRead: now}}
+
+	got := calculateCPUPercentWindows(v)
+	if got != 0.0 {
+		t.Errorf("calculateCPUPercentWindows() = %v, want 0 for zero-duration read", got)
+	}
+}
+
+func TestMemoryUsageWithoutCache(t *testing.T) {
+	cases := []struct {
+		name string
+		v    *container.StatsResponse
+		want uint64
+	}{
+		{
+			name: "cgroup v2 excludes inactive_file",
+			v: &container.StatsResponse{
+				Stats: container.Stats{
+					MemoryStats: container.MemoryStats{
+						Usage: 500,
+						Stats: map[string]uint64{"inactive_file": 200},
+					},
+				},
+			},
+			want: 300,
+		},
+		{
+			name: "cgroup v1 falls back to cache",
+			v: &container.StatsResponse{
+				Stats: container.Stats{
+					MemoryStats: container.MemoryStats{
+						Usage: 500,
+						Stats: map[string]uint64{"cache": 150},
+					},
+				},
+			},
+			want: 350,
+		},
+		{
+			name: "no cache figure reports raw usage",
+			v: &container.StatsResponse{
+				Stats: container.Stats{
+					MemoryStats: container.MemoryStats{Usage: 500},
+				},
+			},
+			want: 500,
+		},
+		{
+			name: "cache larger than usage is ignored rather than underflowing",
+			v: &container.StatsResponse{
+				Stats: container.Stats{
+					MemoryStats: container.MemoryStats{
+						Usage: 100,
+						Stats: map[string]uint64{"inactive_file": 200},
+					},
+				},
+			},
+			want: 100,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := memoryUsageWithoutCache(tc.v)
+			if got != tc.want {
+				t.Errorf("memoryUsageWithoutCache() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
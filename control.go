@@ -0,0 +1,152 @@
+package main
+
+import (
+	"crypto/subtle"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// registerControlRoutes wires the container lifecycle control plane
+// (start/stop/restart/pause/unpause/remove/logs) onto the default mux.
+// It is opt-in: the caller must pass -enable-control, and every request
+// must carry the configured bearer token, since these routes can stop
+// or delete containers.
+func registerControlRoutes(monitor *Monitor, token string) {
+	http.HandleFunc("/api/containers/", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, token) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		id, action, ok := parseContainerPath(r.URL.Path)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodPost && action == "start":
+			respondAction(w, monitor.client.ContainerStart(monitor.ctx, id, container.StartOptions{}))
+		case r.Method == http.MethodPost && action == "stop":
+			respondAction(w, monitor.client.ContainerStop(monitor.ctx, id, container.StopOptions{}))
+		case r.Method == http.MethodPost && action == "restart":
+			respondAction(w, monitor.client.ContainerRestart(monitor.ctx, id, container.StopOptions{}))
+		case r.Method == http.MethodPost && action == "pause":
+			respondAction(w, monitor.client.ContainerPause(monitor.ctx, id))
+		case r.Method == http.MethodPost && action == "unpause":
+			respondAction(w, monitor.client.ContainerUnpause(monitor.ctx, id))
+		case r.Method == http.MethodDelete && action == "":
+			respondAction(w, monitor.client.ContainerRemove(monitor.ctx, id, container.RemoveOptions{}))
+		case r.Method == http.MethodGet && action == "logs":
+			streamContainerLogs(w, r, monitor, id)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// parseContainerPath splits "/api/containers/{id}[/{action}]" into its
+// container ID and optional trailing action segment.
+func parseContainerPath(path string) (id, action string, ok bool) {
+	rest := strings.TrimPrefix(path, "/api/containers/")
+	rest = strings.Trim(rest, "/")
+	if rest == "" {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1], true
+	}
+	return parts[0], "", true
+}
+
+// authorized reports whether r carries the configured bearer token. The
+// comparison is constant-time since this guards destructive actions
+// (stop/remove/etc.) behind a shared secret.
+func authorized(r *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+	given := strings.TrimPrefix(header, prefix)
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// respondAction writes a 204 on success or forwards the Docker error.
+func respondAction(w http.ResponseWriter, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// streamContainerLogs writes a container's logs to w, honoring the
+// ?tail= and ?follow= query parameters, streaming as new lines arrive
+// when follow=true. Containers without a TTY multiplex stdout/stderr
+// behind an 8-byte frame header per Docker's wire format, so that case
+// is demultiplexed through stdcopy before being written out.
+func streamContainerLogs(w http.ResponseWriter, r *http.Request, monitor *Monitor, id string) {
+	follow := r.URL.Query().Get("follow") == "true"
+	tail := r.URL.Query().Get("tail")
+	if tail == "" {
+		tail = "all"
+	}
+
+	info, err := monitor.client.ContainerInspect(monitor.ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tty := info.Config != nil && info.Config.Tty
+
+	logs, err := monitor.client.ContainerLogs(monitor.ctx, id, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     follow,
+		Tail:       tail,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer logs.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	out := flushWriter{w}
+
+	if tty {
+		if _, err := io.Copy(out, logs); err != nil && err != io.EOF {
+			log.Printf("Warning: log stream for container %s ended: %v", shortID(id), err)
+		}
+		return
+	}
+
+	if _, err := stdcopy.StdCopy(out, out, logs); err != nil && err != io.EOF {
+		log.Printf("Warning: log stream for container %s ended: %v", shortID(id), err)
+	}
+}
+
+// flushWriter wraps an http.ResponseWriter so every Write is immediately
+// flushed to the client, which follow=true streaming depends on.
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (fw flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if flusher, ok := fw.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
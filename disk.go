@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// maxVolumeInodeWalk caps how many entries volumeInodes will count
+// across all of a single container's mounts before giving up, so one
+// large bind-mounted volume (e.g. a database data dir) can't make a
+// refresh run far past
+// -disk-interval.
+const maxVolumeInodeWalk = 200_000
+
+// DiskPoller periodically refreshes DiskUsageBytes/DiskUsageInodes for
+// every tracked container. It's opt-in (-disk) and runs on its own,
+// slower ticker, since asking the daemon for SizeRw/SizeRootFs is
+// expensive and shouldn't be done on every stats frame.
+type DiskPoller struct {
+	client    *client.Client
+	collector *Collector
+	interval  time.Duration
+}
+
+// NewDiskPoller creates a DiskPoller that refreshes every interval.
+func NewDiskPoller(cli *client.Client, collector *Collector, interval time.Duration) *DiskPoller {
+	return &DiskPoller{client: cli, collector: collector, interval: interval}
+}
+
+// Run refreshes disk usage immediately and then on every tick until
+// ctx is cancelled.
+func (p *DiskPoller) Run(ctx context.Context) {
+	p.refresh(ctx)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.refresh(ctx)
+		}
+	}
+}
+
+// refresh lists containers with Size:true to get each one's rootfs
+// size and merges it, plus a best-effort volume inode count, into the
+// collector's stats table. Only containers matching the collector's
+// -container/-label/-all filter are inspected and walked; the filter
+// exists precisely to bound how much of this expensive work happens.
+func (p *DiskPoller) refresh(ctx context.Context) {
+	containers, err := p.client.ContainerList(ctx, container.ListOptions{All: p.collector.filter.All, Size: true})
+	if err != nil {
+		log.Printf("Warning: failed to list containers for disk usage: %v", err)
+		return
+	}
+
+	for _, ctr := range containers {
+		if ctx.Err() != nil {
+			return
+		}
+		name := containerDisplayName(ctr.Names, ctr.ID)
+		if !p.collector.filter.matches(name, ctr.ID, ctr.Labels) {
+			continue
+		}
+
+		bytes := uint64(ctr.SizeRootFs)
+		inodes := p.volumeInodes(ctx, ctr.ID)
+		p.collector.UpdateDisk(ctr.ID, bytes, inodes)
+	}
+}
+
+// volumeInodes best-effort counts inodes under each of a container's
+// bind-mounted volumes, returning 0 if the mounts can't be inspected or
+// their source paths aren't reachable from this host (e.g. a remote
+// Docker daemon). The walk bails out early once ctx is cancelled or
+// maxVolumeInodeWalk is reached, so one oversized volume can't block a
+// refresh indefinitely.
+func (p *DiskPoller) volumeInodes(ctx context.Context, containerID string) uint64 {
+	info, err := p.client.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return 0
+	}
+
+	var total uint64
+	stop := errors.New("stop walk")
+	for _, m := range info.Mounts {
+		if m.Source == "" {
+			continue
+		}
+		err := filepath.Walk(m.Source, func(_ string, fi os.FileInfo, err error) error {
+			if ctx.Err() != nil || total >= maxVolumeInodeWalk {
+				return stop
+			}
+			if err == nil && fi != nil {
+				total++
+			}
+			return nil
+		})
+		if err != nil && err != stop && ctx.Err() == nil {
+			log.Printf("Warning: failed to walk volume %s for container %s: %v", m.Source, shortID(containerID), err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return total
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ContainerFilter selects which containers are tracked or displayed,
+// by name/ID glob and required labels. It mirrors the include-list
+// shape of telegraf's docker input (container_name_include,
+// docker_label_include): an empty Names or Labels list matches
+// everything, a non-empty one must match at least one pattern (or, for
+// labels, every requested key=value pair).
+type ContainerFilter struct {
+	Names  []string // glob patterns matched against container name or ID
+	Labels []string // "key=value" pairs that must all be present; "key" alone just requires the key
+	All    bool     // include stopped containers when listing
+}
+
+// matches reports whether a container with the given name, ID, and
+// labels satisfies the filter.
+func (f ContainerFilter) matches(name, id string, labels map[string]string) bool {
+	if len(f.Names) > 0 && !matchesAnyGlob(f.Names, name, id) {
+		return false
+	}
+
+	for _, kv := range f.Labels {
+		key, want, hasValue := strings.Cut(kv, "=")
+		got, ok := labels[key]
+		if !ok {
+			return false
+		}
+		if hasValue && got != want {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesAnyGlob reports whether name or id matches any of the given
+// shell glob patterns (see path.Match). id is matched against both its
+// full and short (12-char) forms, since users naturally pass the short
+// ID shown by `docker ps` and the dashboard's own CONTAINER ID column.
+func matchesAnyGlob(patterns []string, name, id string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, id); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, shortID(id)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filterFromQuery builds a ContainerFilter from the ?name=&label=&all=
+// query parameters of an *http.Request, mirroring the
+// -container/-label/-all CLI flags. ?all= only sets the All field:
+// FilteredSnapshot narrows by name and label and ignores All, so
+// ?all=true does not add stopped containers the Collector isn't
+// already tracking.
+func filterFromQuery(r *http.Request) ContainerFilter {
+	query := r.URL.Query()
+	return ContainerFilter{
+		Names:  query["name"],
+		Labels: query["label"],
+		All:    query.Get("all") == "true",
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+import "strings"
+
+// stringSliceFlag is a repeatable flag.Value that collects each
+// occurrence of the flag into a slice, e.g. -label app=web -label
+// tier=backend.
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
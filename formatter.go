@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+)
+
+// StatsContext renders container stats into one of the supported
+// output formats, modeled after `docker stats --format`: table (the
+// default interactive view), json, csv, raw (tab-separated), or an
+// arbitrary text/template string such as "{{.Name}}: {{.CPUPercent}}%".
+type StatsContext struct {
+	Format string
+	Output io.Writer
+
+	// ShowDisk adds a DISK column to the table format; set when the
+	// monitor was started with -disk. Other formats always include
+	// the disk fields since they cost nothing to render when zero.
+	ShowDisk bool
+}
+
+// NewStatsContext builds a StatsContext for the given -format value.
+func NewStatsContext(format string, out io.Writer) *StatsContext {
+	return &StatsContext{Format: format, Output: out}
+}
+
+// Write renders stats in the configured format.
+func (sc *StatsContext) Write(stats []ContainerStats) error {
+	switch sc.Format {
+	case "", "table":
+		writeTable(sc.Output, stats, sc.ShowDisk)
+		return nil
+	case "json":
+		return json.NewEncoder(sc.Output).Encode(stats)
+	case "csv":
+		return writeCSV(sc.Output, stats)
+	case "raw":
+		return writeRaw(sc.Output, stats)
+	default:
+		return writeGoTemplate(sc.Output, sc.Format, stats)
+	}
+}
+
+// writeTable renders the classic fixed-width table, adding a DISK
+// column when showDisk is set.
+func writeTable(w io.Writer, stats []ContainerStats, showDisk bool) {
+	if len(stats) == 0 {
+		fmt.Fprintln(w, "No running containers found.")
+		return
+	}
+
+	if showDisk {
+		fmt.Fprintf(w, "%-15s %-30s %10s %20s %15s %15s %15s %15s\n",
+			"CONTAINER ID", "NAME", "CPU %", "MEMORY", "MEM %", "NET I/O", "BLOCK I/O", "DISK")
+	} else {
+		fmt.Fprintf(w, "%-15s %-30s %10s %20s %15s %15s %15s\n",
+			"CONTAINER ID", "NAME", "CPU %", "MEMORY", "MEM %", "NET I/O", "BLOCK I/O")
+	}
+	fmt.Fprintln(w, "-----------------------------------------------------------------------------------------------------------------------------------")
+
+	for _, stat := range stats {
+		memUsage := fmt.Sprintf("%s / %s", formatBytes(stat.MemoryUsage), formatBytes(stat.MemoryLimit))
+		netIO := fmt.Sprintf("%s / %s", formatBytes(stat.NetworkRx), formatBytes(stat.NetworkTx))
+		blockIO := fmt.Sprintf("%s / %s", formatBytes(stat.BlockRead), formatBytes(stat.BlockWrite))
+
+		if showDisk {
+			fmt.Fprintf(w, "%-15s %-30s %9.2f%% %20s %14.2f%% %15s %15s %15s\n",
+				stat.ID, stat.Name, stat.CPUPercent, memUsage, stat.MemoryPercent, netIO, blockIO,
+				formatBytes(stat.DiskUsageBytes),
+			)
+			continue
+		}
+
+		fmt.Fprintf(w, "%-15s %-30s %9.2f%% %20s %14.2f%% %15s %15s\n",
+			stat.ID,
+			stat.Name,
+			stat.CPUPercent,
+			memUsage,
+			stat.MemoryPercent,
+			netIO,
+			blockIO,
+		)
+	}
+}
+
+// writeCSV renders stats as CSV with a header row, using raw byte
+// counts rather than human-readable sizes so the output stays
+// machine-parseable.
+func writeCSV(w io.Writer, stats []ContainerStats) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	header := []string{"id", "name", "cpu_percent", "memory_usage", "memory_limit", "memory_percent", "network_rx", "network_tx", "block_read", "block_write", "disk_usage_bytes", "disk_usage_inodes"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, stat := range stats {
+		record := []string{
+			stat.ID,
+			stat.Name,
+			fmt.Sprintf("%.2f", stat.CPUPercent),
+			fmt.Sprintf("%d", stat.MemoryUsage),
+			fmt.Sprintf("%d", stat.MemoryLimit),
+			fmt.Sprintf("%.2f", stat.MemoryPercent),
+			fmt.Sprintf("%d", stat.NetworkRx),
+			fmt.Sprintf("%d", stat.NetworkTx),
+			fmt.Sprintf("%d", stat.BlockRead),
+			fmt.Sprintf("%d", stat.BlockWrite),
+			fmt.Sprintf("%d", stat.DiskUsageBytes),
+			fmt.Sprintf("%d", stat.DiskUsageInodes),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRaw renders one tab-separated line per container, with no
+// header, similar to `docker stats --format raw`.
+func writeRaw(w io.Writer, stats []ContainerStats) error {
+	for _, stat := range stats {
+		_, err := fmt.Fprintf(w, "%s\t%s\t%.2f%%\t%d\t%d\t%.2f%%\t%d\t%d\t%d\t%d\t%d\t%d\n",
+			stat.ID, stat.Name, stat.CPUPercent, stat.MemoryUsage, stat.MemoryLimit,
+			stat.MemoryPercent, stat.NetworkRx, stat.NetworkTx, stat.BlockRead, stat.BlockWrite,
+			stat.DiskUsageBytes, stat.DiskUsageInodes)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeGoTemplate treats format as a text/template string and executes
+// it once per container, e.g. "{{.Name}}: {{.CPUPercent}}%".
+func writeGoTemplate(w io.Writer, format string, stats []ContainerStats) error {
+	tmpl, err := template.New("format").Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid -format template: %w", err)
+	}
+
+	for _, stat := range stats {
+		if err := tmpl.Execute(w, stat); err != nil {
+			return fmt.Errorf("failed to render -format template: %w", err)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
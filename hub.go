@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// Hub fans out the monitor's latest stats snapshot to every connected
+// WebSocket subscriber whenever the collector reports a new sample,
+// replacing per-client polling with a single shared push.
+type Hub struct {
+	monitor *Monitor
+
+	mu          sync.Mutex
+	subscribers map[chan []ContainerStats]struct{}
+}
+
+// NewHub creates a Hub bound to monitor. It does nothing until Run is
+// called.
+func NewHub(monitor *Monitor) *Hub {
+	return &Hub{
+		monitor:     monitor,
+		subscribers: make(map[chan []ContainerStats]struct{}),
+	}
+}
+
+// Run reads from the monitor's collector update signal and broadcasts
+// a fresh snapshot to every subscriber each time it fires. Run blocks
+// until ctx is cancelled.
+func (h *Hub) Run(ctx context.Context) {
+	updates := h.monitor.collector.Updates()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-updates:
+			stats, err := h.monitor.GetContainerStats(ContainerFilter{})
+			if err != nil {
+				continue
+			}
+			h.broadcast(stats)
+		}
+	}
+}
+
+// subscribe registers a new subscriber and returns its channel. The
+// channel is buffered by one frame; broadcast drops frames for slow
+// subscribers instead of blocking the hub.
+func (h *Hub) subscribe() chan []ContainerStats {
+	ch := make(chan []ContainerStats, 1)
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+// unsubscribe removes and closes a subscriber's channel.
+func (h *Hub) unsubscribe(ch chan []ContainerStats) {
+	h.mu.Lock()
+	delete(h.subscribers, ch)
+	h.mu.Unlock()
+	close(ch)
+}
+
+// broadcast pushes stats to every subscriber.
+func (h *Hub) broadcast(stats []ContainerStats) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- stats:
+		default:
+		}
+	}
+}
@@ -12,8 +12,9 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // ContainerStats holds the statistics for a container
@@ -28,125 +29,59 @@ type ContainerStats struct {
 	NetworkTx     uint64  `json:"network_tx"`
 	BlockRead     uint64  `json:"block_read"`
 	BlockWrite    uint64  `json:"block_write"`
+
+	// DiskUsageBytes and DiskUsageInodes are only populated when the
+	// monitor is started with -disk; they're zero otherwise.
+	DiskUsageBytes  uint64 `json:"disk_usage_bytes"`
+	DiskUsageInodes uint64 `json:"disk_usage_inodes"`
 }
 
-// Monitor represents the Docker monitoring service
+// Monitor represents the Docker monitoring service. It owns a
+// background Collector that keeps last-known stats for every running
+// container, so reads never hit the Docker API directly.
 type Monitor struct {
-	client *client.Client
-	ctx    context.Context
+	client    *client.Client
+	ctx       context.Context
+	cancel    context.CancelFunc
+	collector *Collector
 }
 
-// NewMonitor creates a new Docker monitor
-func NewMonitor() (*Monitor, error) {
+// NewMonitor creates a new Docker monitor that tracks only containers
+// matching filter.
+func NewMonitor(filter ContainerFilter) (*Monitor, error) {
 	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Docker client: %w", err)
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Monitor{
-		client: cli,
-		ctx:    context.Background(),
+		client:    cli,
+		ctx:       ctx,
+		cancel:    cancel,
+		collector: NewCollector(ctx, cli, filter),
 	}, nil
 }
 
-// GetContainerStats retrieves statistics for all running containers
-func (m *Monitor) GetContainerStats() ([]ContainerStats, error) {
-	containers, err := m.client.ContainerList(m.ctx, container.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list containers: %w", err)
-	}
-
-	var stats []ContainerStats
-	for _, ctr := range containers {
-		// Get container name
-		containerName := ctr.ID[:12] // Use ID as fallback
-		if len(ctr.Names) > 0 {
-			containerName = ctr.Names[0]
+// Start runs the stats collector in the background. It must be called
+// before GetContainerStats returns anything useful.
+func (m *Monitor) Start() {
+	go func() {
+		if err := m.collector.Run(); err != nil && m.ctx.Err() == nil {
+			log.Printf("Error running stats collector: %v", err)
 		}
-		
-		stat, err := m.getContainerStat(ctr.ID, containerName)
-		if err != nil {
-			log.Printf("Warning: failed to get stats for container %s: %v", ctr.ID[:12], err)
-			continue
-		}
-		stats = append(stats, stat)
-	}
-
-	return stats, nil
+	}()
 }
 
-// getContainerStat retrieves statistics for a single container
-func (m *Monitor) getContainerStat(containerID, containerName string) (ContainerStats, error) {
-	stats, err := m.client.ContainerStats(m.ctx, containerID, false)
-	if err != nil {
-		return ContainerStats{}, err
-	}
-	defer stats.Body.Close()
-
-	var v container.StatsResponse
-	if err := json.NewDecoder(stats.Body).Decode(&v); err != nil {
-		return ContainerStats{}, err
-	}
-
-	// Calculate CPU percentage
-	cpuPercent := calculateCPUPercent(&v)
-
-	// Calculate memory percentage
-	var memPercent float64
-	if v.MemoryStats.Limit > 0 {
-		memPercent = float64(v.MemoryStats.Usage) / float64(v.MemoryStats.Limit) * 100.0
-	}
-
-	// Calculate network stats
-	var rxBytes, txBytes uint64
-	for _, network := range v.Networks {
-		rxBytes += network.RxBytes
-		txBytes += network.TxBytes
-	}
-
-	// Calculate block IO stats
-	var blockRead, blockWrite uint64
-	for _, bio := range v.BlkioStats.IoServiceBytesRecursive {
-		if bio.Op == "Read" {
-			blockRead += bio.Value
-		} else if bio.Op == "Write" {
-			blockWrite += bio.Value
-		}
-	}
-
-	// Safely truncate container ID
-	displayID := containerID
-	if len(containerID) > 12 {
-		displayID = containerID[:12]
-	}
-
-	return ContainerStats{
-		ID:            displayID,
-		Name:          containerName,
-		CPUPercent:    cpuPercent,
-		MemoryUsage:   v.MemoryStats.Usage,
-		MemoryLimit:   v.MemoryStats.Limit,
-		MemoryPercent: memPercent,
-		NetworkRx:     rxBytes,
-		NetworkTx:     txBytes,
-		BlockRead:     blockRead,
-		BlockWrite:    blockWrite,
-	}, nil
+// GetContainerStats returns the latest known statistics for every
+// tracked container matching filter, sorted by name.
+func (m *Monitor) GetContainerStats(filter ContainerFilter) ([]ContainerStats, error) {
+	return m.collector.FilteredSnapshot(filter), nil
 }
 
-// calculateCPUPercent calculates the CPU usage percentage
-func calculateCPUPercent(v *container.StatsResponse) float64 {
-	cpuDelta := float64(v.CPUStats.CPUUsage.TotalUsage) - float64(v.PreCPUStats.CPUUsage.TotalUsage)
-	systemDelta := float64(v.CPUStats.SystemUsage) - float64(v.PreCPUStats.SystemUsage)
-
-	if systemDelta > 0.0 && cpuDelta > 0.0 {
-		return (cpuDelta / systemDelta) * float64(len(v.CPUStats.CPUUsage.PercpuUsage)) * 100.0
-	}
-	return 0.0
-}
-
-// Close closes the Docker client connection
+// Close stops the collector and closes the Docker client connection
 func (m *Monitor) Close() error {
+	m.cancel()
 	return m.client.Close()
 }
 
@@ -164,64 +99,47 @@ func formatBytes(bytes uint64) string {
 	return fmt.Sprintf("%.2f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-// printStats prints container statistics to console
-func printStats(stats []ContainerStats) {
-	fmt.Print("\033[2J\033[H") // Clear screen
-	fmt.Println("Docker Container Monitor")
-	fmt.Println("========================")
-	fmt.Printf("Time: %s\n\n", time.Now().Format("2006-01-02 15:04:05"))
-
-	if len(stats) == 0 {
-		fmt.Println("No running containers found.")
+// printOnce fetches the latest stats and renders them through statsCtx,
+// clearing the screen and printing a header first when rendering the
+// interactive table so the other formats stay clean for piping.
+func printOnce(monitor *Monitor, statsCtx *StatsContext) {
+	stats, err := monitor.GetContainerStats(ContainerFilter{})
+	if err != nil {
+		log.Printf("Error getting container stats: %v", err)
 		return
 	}
 
-	fmt.Printf("%-15s %-30s %10s %20s %15s %15s %15s %15s\n",
-		"CONTAINER ID", "NAME", "CPU %", "MEMORY", "MEM %", "NET I/O", "BLOCK I/O", "")
-	fmt.Println("-----------------------------------------------------------------------------------------------------------------------------------")
-
-	for _, stat := range stats {
-		memUsage := fmt.Sprintf("%s / %s", formatBytes(stat.MemoryUsage), formatBytes(stat.MemoryLimit))
-		netIO := fmt.Sprintf("%s / %s", formatBytes(stat.NetworkRx), formatBytes(stat.NetworkTx))
-		blockIO := fmt.Sprintf("%s / %s", formatBytes(stat.BlockRead), formatBytes(stat.BlockWrite))
-
-		fmt.Printf("%-15s %-30s %9.2f%% %20s %14.2f%% %15s %15s\n",
-			stat.ID,
-			stat.Name,
-			stat.CPUPercent,
-			memUsage,
-			stat.MemoryPercent,
-			netIO,
-			blockIO,
-		)
+	if statsCtx.Format == "" || statsCtx.Format == "table" {
+		fmt.Print("\033[2J\033[H") // Clear screen
+		fmt.Println("Docker Container Monitor")
+		fmt.Println("========================")
+		fmt.Printf("Time: %s\n\n", time.Now().Format("2006-01-02 15:04:05"))
+	}
+
+	if err := statsCtx.Write(stats); err != nil {
+		log.Printf("Error formatting stats: %v", err)
 	}
 }
 
-// startCLI starts the CLI monitoring mode
-func startCLI(monitor *Monitor, interval int) {
+// startCLI starts the CLI monitoring mode, rendering stats through
+// statsCtx on every tick. With noStream, it prints a single snapshot
+// and returns instead of looping, for scripting.
+func startCLI(monitor *Monitor, interval int, statsCtx *StatsContext, noStream bool) {
+	printOnce(monitor, statsCtx)
+	if noStream {
+		return
+	}
+
 	ticker := time.NewTicker(time.Duration(interval) * time.Second)
 	defer ticker.Stop()
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	// Print initial stats
-	stats, err := monitor.GetContainerStats()
-	if err != nil {
-		log.Printf("Error getting container stats: %v", err)
-	} else {
-		printStats(stats)
-	}
-
 	for {
 		select {
 		case <-ticker.C:
-			stats, err := monitor.GetContainerStats()
-			if err != nil {
-				log.Printf("Error getting container stats: %v", err)
-				continue
-			}
-			printStats(stats)
+			printOnce(monitor, statsCtx)
 		case <-sigChan:
 			fmt.Println("\nShutting down...")
 			return
@@ -230,9 +148,9 @@ func startCLI(monitor *Monitor, interval int) {
 }
 
 // startAPI starts the REST API server
-func startAPI(monitor *Monitor, port int) {
+func startAPI(monitor *Monitor, port int, metricsLabels []string, enableControl bool, controlToken string) {
 	http.HandleFunc("/api/stats", func(w http.ResponseWriter, r *http.Request) {
-		stats, err := monitor.GetContainerStats()
+		stats, err := monitor.GetContainerStats(filterFromQuery(r))
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -242,6 +160,21 @@ func startAPI(monitor *Monitor, port int) {
 		json.NewEncoder(w).Encode(stats)
 	})
 
+	prometheus.MustRegister(newStatsCollector(monitor.collector, metricsLabels))
+	http.Handle("/metrics", promhttp.Handler())
+
+	hub := NewHub(monitor)
+	go hub.Run(monitor.ctx)
+	registerStreamRoute(hub)
+
+	if enableControl {
+		if controlToken == "" {
+			log.Fatal("-enable-control requires -control-token to be set")
+		}
+		registerControlRoutes(monitor, controlToken)
+		log.Printf("Container control API enabled (start/stop/restart/pause/unpause/remove/logs)")
+	}
+
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html")
 		fmt.Fprint(w, dashboardHTML)
@@ -251,6 +184,7 @@ func startAPI(monitor *Monitor, port int) {
 	log.Printf("Starting API server on http://localhost%s", addr)
 	log.Printf("Dashboard available at http://localhost%s", addr)
 	log.Printf("API endpoint: http://localhost%s/api/stats", addr)
+	log.Printf("Prometheus metrics: http://localhost%s/metrics", addr)
 
 	if err := http.ListenAndServe(addr, nil); err != nil {
 		log.Fatalf("Failed to start API server: %v", err)
@@ -330,6 +264,31 @@ var dashboardHTML = `
             color: #999;
             font-family: 'Courier New', monospace;
         }
+        .sparkline {
+            display: block;
+            margin: 4px 0;
+        }
+        .controls {
+            display: flex;
+            flex-wrap: wrap;
+            gap: 8px;
+            margin-top: 15px;
+        }
+        .controls button {
+            border: none;
+            border-radius: 6px;
+            padding: 6px 12px;
+            font-size: 0.85em;
+            cursor: pointer;
+            background: #667eea;
+            color: white;
+        }
+        .controls button.danger {
+            background: #c33;
+        }
+        .controls button:hover {
+            opacity: 0.85;
+        }
         .stats-row {
             display: grid;
             grid-template-columns: repeat(2, 1fr);
@@ -426,71 +385,186 @@ var dashboardHTML = `
             return parseFloat((bytes / Math.pow(k, i)).toFixed(2)) + ' ' + sizes[i];
         }
 
+        // Rolling per-container history used to draw sparklines. Keyed
+        // by container ID, capped at HISTORY_LIMIT points.
+        const HISTORY_LIMIT = 120;
+        const history = {};
+
+        function recordHistory(stat) {
+            let points = history[stat.id];
+            if (!points) {
+                points = [];
+                history[stat.id] = points;
+            }
+            points.push({ cpu: stat.cpu_percent, mem: stat.memory_percent });
+            if (points.length > HISTORY_LIMIT) points.shift();
+            return points;
+        }
+
+        function sparkline(points, key, max) {
+            const width = 120, height = 30;
+            if (points.length < 2) return '<svg class="sparkline" width="' + width + '" height="' + height + '"></svg>';
+
+            const step = width / (HISTORY_LIMIT - 1);
+            const offset = HISTORY_LIMIT - points.length;
+            const coords = points.map((p, i) => {
+                const x = (offset + i) * step;
+                const y = height - (Math.min(p[key], max) / max) * height;
+                return x.toFixed(1) + ',' + y.toFixed(1);
+            }).join(' ');
+
+            return '<svg class="sparkline" width="' + width + '" height="' + height + '">' +
+                '<polyline points="' + coords + '" fill="none" stroke="#667eea" stroke-width="2"/></svg>';
+        }
+
+        function renderStats(data) {
+            const container = document.getElementById('stats-container');
+            const timestamp = document.getElementById('timestamp');
+
+            timestamp.textContent = 'Last updated: ' + new Date().toLocaleString();
+
+            if (!data || data.length === 0) {
+                container.innerHTML = '<div class="no-containers">No running containers found</div>';
+                return;
+            }
+
+            let html = '<div class="stats-grid">';
+            data.forEach(stat => {
+                const points = recordHistory(stat);
+                html += '<div class="card">';
+                html += '<div class="card-header">';
+                html += '<div class="container-name">' + stat.name + '</div>';
+                html += '<div class="container-id">' + stat.id + '</div>';
+                html += '</div>';
+                html += '<div class="stats-row">';
+                html += '<div class="stat">';
+                html += '<div class="stat-label">CPU Usage</div>';
+                html += '<div class="stat-value">' + stat.cpu_percent.toFixed(2) + '%</div>';
+                html += sparkline(points, 'cpu', 100);
+                html += '<div class="progress-bar">';
+                html += '<div class="progress-fill" style="width: ' + Math.min(stat.cpu_percent, 100) + '%"></div>';
+                html += '</div></div>';
+                html += '<div class="stat">';
+                html += '<div class="stat-label">Memory Usage</div>';
+                html += '<div class="stat-value">' + stat.memory_percent.toFixed(2) + '%</div>';
+                html += sparkline(points, 'mem', 100);
+                html += '<div class="progress-bar">';
+                html += '<div class="progress-fill" style="width: ' + stat.memory_percent + '%"></div>';
+                html += '</div>';
+                html += '<div style="font-size: 0.8em; color: #666; margin-top: 5px;">';
+                html += formatBytes(stat.memory_usage) + ' / ' + formatBytes(stat.memory_limit);
+                html += '</div></div></div>';
+                html += '<div class="stats-row">';
+                html += '<div class="stat">';
+                html += '<div class="stat-label">Network I/O</div>';
+                html += '<div class="stat-value" style="font-size: 0.9em;">';
+                html += '&#8595; ' + formatBytes(stat.network_rx) + '<br>';
+                html += '&#8593; ' + formatBytes(stat.network_tx);
+                html += '</div></div>';
+                html += '<div class="stat">';
+                html += '<div class="stat-label">Block I/O</div>';
+                html += '<div class="stat-value" style="font-size: 0.9em;">';
+                html += 'Read: ' + formatBytes(stat.block_read) + '<br>';
+                html += 'Write: ' + formatBytes(stat.block_write);
+                html += '</div></div></div>';
+                if (stat.disk_usage_bytes > 0 || stat.disk_usage_inodes > 0) {
+                    html += '<div class="stats-row">';
+                    html += '<div class="stat">';
+                    html += '<div class="stat-label">Disk Usage</div>';
+                    html += '<div class="stat-value" style="font-size: 0.9em;">';
+                    html += formatBytes(stat.disk_usage_bytes) + ' (' + stat.disk_usage_inodes + ' inodes)';
+                    html += '</div></div></div>';
+                }
+                html += '<div class="controls">';
+                ['start', 'stop', 'restart', 'pause', 'unpause'].forEach(action => {
+                    html += '<button onclick="controlAction(\'' + stat.id + '\', \'' + action + '\')">' + action + '</button>';
+                });
+                html += '<button onclick="viewLogs(\'' + stat.id + '\')">logs</button>';
+                html += '<button class="danger" onclick="controlAction(\'' + stat.id + '\', \'\', \'DELETE\')">remove</button>';
+                html += '</div></div>';
+            });
+            html += '</div>';
+
+            container.innerHTML = html;
+        }
+
         function updateStats() {
             fetch('/api/stats')
                 .then(response => response.json())
-                .then(data => {
-                    const container = document.getElementById('stats-container');
-                    const timestamp = document.getElementById('timestamp');
-                    
-                    timestamp.textContent = 'Last updated: ' + new Date().toLocaleString();
-
-                    if (!data || data.length === 0) {
-                        container.innerHTML = '<div class="no-containers">No running containers found</div>';
-                        return;
-                    }
-
-                    let html = '<div class="stats-grid">';
-                    data.forEach(stat => {
-                        html += '<div class="card">';
-                        html += '<div class="card-header">';
-                        html += '<div class="container-name">' + stat.name + '</div>';
-                        html += '<div class="container-id">' + stat.id + '</div>';
-                        html += '</div>';
-                        html += '<div class="stats-row">';
-                        html += '<div class="stat">';
-                        html += '<div class="stat-label">CPU Usage</div>';
-                        html += '<div class="stat-value">' + stat.cpu_percent.toFixed(2) + '%</div>';
-                        html += '<div class="progress-bar">';
-                        html += '<div class="progress-fill" style="width: ' + Math.min(stat.cpu_percent, 100) + '%"></div>';
-                        html += '</div></div>';
-                        html += '<div class="stat">';
-                        html += '<div class="stat-label">Memory Usage</div>';
-                        html += '<div class="stat-value">' + stat.memory_percent.toFixed(2) + '%</div>';
-                        html += '<div class="progress-bar">';
-                        html += '<div class="progress-fill" style="width: ' + stat.memory_percent + '%"></div>';
-                        html += '</div>';
-                        html += '<div style="font-size: 0.8em; color: #666; margin-top: 5px;">';
-                        html += formatBytes(stat.memory_usage) + ' / ' + formatBytes(stat.memory_limit);
-                        html += '</div></div></div>';
-                        html += '<div class="stats-row">';
-                        html += '<div class="stat">';
-                        html += '<div class="stat-label">Network I/O</div>';
-                        html += '<div class="stat-value" style="font-size: 0.9em;">';
-                        html += '&#8595; ' + formatBytes(stat.network_rx) + '<br>';
-                        html += '&#8593; ' + formatBytes(stat.network_tx);
-                        html += '</div></div>';
-                        html += '<div class="stat">';
-                        html += '<div class="stat-label">Block I/O</div>';
-                        html += '<div class="stat-value" style="font-size: 0.9em;">';
-                        html += 'Read: ' + formatBytes(stat.block_read) + '<br>';
-                        html += 'Write: ' + formatBytes(stat.block_write);
-                        html += '</div></div></div></div>';
-                    });
-                    html += '</div>';
-
-                    container.innerHTML = html;
-                })
+                .then(renderStats)
                 .catch(error => {
                     console.error('Error fetching stats:', error);
-                    document.getElementById('stats-container').innerHTML = 
+                    document.getElementById('stats-container').innerHTML =
                         '<div class="error">Error loading container statistics. Please ensure Docker is running and accessible.</div>';
                 });
         }
 
-        // Update stats immediately and then every 2 seconds
-        updateStats();
-        setInterval(updateStats, 2000);
+        // Prefer a live WebSocket push over polling; fall back to the
+        // 2-second poll transparently if the upgrade fails or the
+        // connection drops.
+        let pollTimer = null;
+
+        function startPolling() {
+            if (pollTimer) return;
+            updateStats();
+            pollTimer = setInterval(updateStats, 2000);
+        }
+
+        function stopPolling() {
+            if (!pollTimer) return;
+            clearInterval(pollTimer);
+            pollTimer = null;
+        }
+
+        function connectStream() {
+            const url = (location.protocol === 'https:' ? 'wss://' : 'ws://') + location.host + '/api/stream';
+            const ws = new WebSocket(url);
+
+            ws.onopen = () => stopPolling();
+            ws.onmessage = event => renderStats(JSON.parse(event.data));
+            ws.onerror = () => startPolling();
+            ws.onclose = () => {
+                startPolling();
+                setTimeout(connectStream, 3000);
+            };
+        }
+
+        // Container control actions require -enable-control on the
+        // server and a bearer token, prompted for once and cached.
+        function controlToken() {
+            let token = localStorage.getItem('controlToken');
+            if (!token) {
+                token = prompt('Control API bearer token:') || '';
+                localStorage.setItem('controlToken', token);
+            }
+            return token;
+        }
+
+        function controlAction(id, action, method) {
+            const path = '/api/containers/' + id + (action ? '/' + action : '');
+            fetch(path, {
+                method: method || 'POST',
+                headers: { 'Authorization': 'Bearer ' + controlToken() },
+            })
+                .then(response => {
+                    if (!response.ok) throw new Error('request failed: ' + response.status);
+                    updateStats();
+                })
+                .catch(error => alert('Action failed: ' + error));
+        }
+
+        function viewLogs(id) {
+            const url = '/api/containers/' + id + '/logs?tail=200';
+            fetch(url, { headers: { 'Authorization': 'Bearer ' + controlToken() } })
+                .then(response => response.text())
+                .then(text => { window.open('').document.write('<pre>' + text + '</pre>'); })
+                .catch(error => alert('Failed to load logs: ' + error));
+        }
+
+        // Start polling immediately so the dashboard has data right
+        // away, then try to upgrade to the WebSocket stream.
+        startPolling();
+        connectStream();
     </script>
 </body>
 </html>
@@ -501,18 +575,53 @@ func main() {
 		apiMode  = flag.Bool("api", false, "Start in API mode with web dashboard")
 		port     = flag.Int("port", 8080, "Port for API server (use with -api)")
 		interval = flag.Int("interval", 2, "Update interval in seconds for CLI mode")
+		format   = flag.String("format", "table", "Output format for CLI mode: table, json, csv, raw, or a Go text/template string")
+		noStream = flag.Bool("no-stream", false, "Print one snapshot and exit instead of streaming (CLI mode)")
+	)
+	var (
+		enableControl = flag.Bool("enable-control", false, "Enable the container control API (start/stop/restart/pause/unpause/remove/logs)")
+		controlToken  = flag.String("control-token", "", "Bearer token required to call the container control API (required with -enable-control)")
+	)
+	var metricsLabels stringSliceFlag
+	flag.Var(&metricsLabels, "metrics-label", "Container label key to expose on /metrics (repeatable, use with -api)")
+
+	var (
+		containerNames  stringSliceFlag
+		containerLabels stringSliceFlag
+		allContainers   = flag.Bool("all", false, "Include stopped containers")
+	)
+	flag.Var(&containerNames, "container", "Container name/ID glob to include (repeatable)")
+	flag.Var(&containerLabels, "label", "Container label (key or key=value) required to include a container (repeatable)")
+
+	var (
+		diskEnabled  = flag.Bool("disk", false, "Report container disk usage (expensive; refreshed on -disk-interval)")
+		diskInterval = flag.Int("disk-interval", 30, "Seconds between disk usage refreshes (use with -disk)")
 	)
 	flag.Parse()
 
-	monitor, err := NewMonitor()
+	filter := ContainerFilter{
+		Names:  containerNames,
+		Labels: containerLabels,
+		All:    *allContainers,
+	}
+
+	monitor, err := NewMonitor(filter)
 	if err != nil {
 		log.Fatalf("Failed to create monitor: %v", err)
 	}
 	defer monitor.Close()
+	monitor.Start()
+
+	if *diskEnabled {
+		poller := NewDiskPoller(monitor.client, monitor.collector, time.Duration(*diskInterval)*time.Second)
+		go poller.Run(monitor.ctx)
+	}
 
 	if *apiMode {
-		startAPI(monitor, *port)
+		startAPI(monitor, *port, metricsLabels, *enableControl, *controlToken)
 	} else {
-		startCLI(monitor, *interval)
+		statsCtx := NewStatsContext(*format, os.Stdout)
+		statsCtx.ShowDisk = *diskEnabled
+		startCLI(monitor, *interval, statsCtx, *noStream)
 	}
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statsCollector adapts a Collector to the prometheus.Collector
+// interface, exporting its latest samples as Prometheus metrics on
+// every /metrics scrape rather than caching values between scrapes.
+type statsCollector struct {
+	collector   *Collector
+	extraLabels []string
+
+	cpuPercent  *prometheus.Desc
+	memoryUsage *prometheus.Desc
+	memoryLimit *prometheus.Desc
+	networkRx   *prometheus.Desc
+	networkTx   *prometheus.Desc
+	blockIO     *prometheus.Desc
+}
+
+// newStatsCollector builds a statsCollector that reads from c and
+// labels every metric with "id", "name", and the given container label
+// keys (selected via -metrics-label).
+func newStatsCollector(c *Collector, extraLabels []string) *statsCollector {
+	labelNames := append([]string{"id", "name"}, extraLabels...)
+	blockIOLabels := append(append([]string{}, labelNames...), "op")
+
+	return &statsCollector{
+		collector:   c,
+		extraLabels: extraLabels,
+		cpuPercent: prometheus.NewDesc("docker_container_cpu_percent",
+			"Container CPU usage as a percentage of available CPU.", labelNames, nil),
+		memoryUsage: prometheus.NewDesc("docker_container_memory_usage_bytes",
+			"Container memory usage in bytes.", labelNames, nil),
+		memoryLimit: prometheus.NewDesc("docker_container_memory_limit_bytes",
+			"Container memory limit in bytes.", labelNames, nil),
+		networkRx: prometheus.NewDesc("docker_container_network_rx_bytes_total",
+			"Total bytes received over the network by the container.", labelNames, nil),
+		networkTx: prometheus.NewDesc("docker_container_network_tx_bytes_total",
+			"Total bytes transmitted over the network by the container.", labelNames, nil),
+		blockIO: prometheus.NewDesc("docker_container_block_io_bytes_total",
+			"Total bytes read from or written to block devices by the container.", blockIOLabels, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (sc *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sc.cpuPercent
+	ch <- sc.memoryUsage
+	ch <- sc.memoryLimit
+	ch <- sc.networkRx
+	ch <- sc.networkTx
+	ch <- sc.blockIO
+}
+
+// Collect implements prometheus.Collector, emitting one set of metrics
+// per tracked container using the collector's latest snapshot.
+func (sc *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	for id, stat := range sc.collector.Entries() {
+		labels := append([]string{stat.ID, stat.Name}, sc.collector.LabelValues(id, sc.extraLabels)...)
+
+		ch <- prometheus.MustNewConstMetric(sc.cpuPercent, prometheus.GaugeValue, stat.CPUPercent, labels...)
+		ch <- prometheus.MustNewConstMetric(sc.memoryUsage, prometheus.GaugeValue, float64(stat.MemoryUsage), labels...)
+		ch <- prometheus.MustNewConstMetric(sc.memoryLimit, prometheus.GaugeValue, float64(stat.MemoryLimit), labels...)
+		ch <- prometheus.MustNewConstMetric(sc.networkRx, prometheus.CounterValue, float64(stat.NetworkRx), labels...)
+		ch <- prometheus.MustNewConstMetric(sc.networkTx, prometheus.CounterValue, float64(stat.NetworkTx), labels...)
+		ch <- prometheus.MustNewConstMetric(sc.blockIO, prometheus.CounterValue, float64(stat.BlockRead), append(append([]string{}, labels...), "read")...)
+		ch <- prometheus.MustNewConstMetric(sc.blockIO, prometheus.CounterValue, float64(stat.BlockWrite), append(append([]string{}, labels...), "write")...)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/websocket"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     sameOrigin,
+}
+
+// sameOrigin rejects cross-origin WebSocket upgrades. Unlike plain HTTP
+// requests, upgrades aren't subject to the browser's same-origin policy
+// or CORS, only to this check, so accepting any origin would let a page
+// on another site open a WebSocket to a locally-reachable dashboard and
+// read the live stats pushed over /api/stream. Requests with no Origin
+// header (non-browser clients) are allowed through.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return u.Host == r.Host
+}
+
+// registerStreamRoute wires /api/stream, which upgrades to a WebSocket
+// and pushes a JSON stats frame every time hub has a new sample. A
+// client whose upgrade fails (old proxy, WebSocket disabled) should
+// keep using /api/stats instead; this endpoint doesn't change that.
+func registerStreamRoute(hub *Hub) {
+	http.HandleFunc("/api/stream", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := wsUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Printf("Warning: websocket upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		updates := hub.subscribe()
+		defer hub.unsubscribe(updates)
+
+		for stats := range updates {
+			if err := conn.WriteJSON(stats); err != nil {
+				return
+			}
+		}
+	})
+}